@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+// initVRFSubCmd returns the "vrf" command tree for runtime VRF job
+// introspection and control. It is merged into the root app's Commands
+// alongside the other subcommands.
+func initVRFSubCmd(client *Client) cli.Command {
+	return cli.Command{
+		Name:  "vrf",
+		Usage: "Commands for interacting with running VRF jobs",
+		Subcommands: []cli.Command{
+			{
+				Name:   "inspect",
+				Usage:  "List active VRF jobs, or dump a job's in-memory fulfillment counts with --job-id",
+				Action: client.VRFInspect,
+				Flags: []cli.Flag{
+					cli.Int64Flag{Name: "job-id", Usage: "job ID to dump respCount for; omitted lists all active VRF jobs"},
+				},
+			},
+			{
+				Name:   "replay",
+				Usage:  "Force a job's listener to re-process a specific request ID",
+				Action: client.VRFReplay,
+				Flags: []cli.Flag{
+					cli.Int64Flag{Name: "job-id", Required: true},
+					cli.StringFlag{Name: "request-id", Required: true},
+				},
+			},
+			{
+				Name:   "pause",
+				Usage:  "Pause request pickup for a job's listener",
+				Action: client.VRFPause,
+				Flags: []cli.Flag{
+					cli.Int64Flag{Name: "job-id", Required: true},
+				},
+			},
+			{
+				Name:   "resume",
+				Usage:  "Resume request pickup for a job's listener after pause",
+				Action: client.VRFResume,
+				Flags: []cli.Flag{
+					cli.Int64Flag{Name: "job-id", Required: true},
+				},
+			},
+		},
+	}
+}
+
+// VRFInspect implements `chainlink vrf inspect`.
+func (cli *Client) VRFInspect(c *cli.Context) error {
+	if !c.IsSet("job-id") {
+		resp, err := cli.HTTP.Get("/v2/vrf/jobs")
+		if err != nil {
+			return err
+		}
+		return cli.renderJSONResponse(resp)
+	}
+	resp, err := cli.HTTP.Get(fmt.Sprintf("/v2/vrf/jobs/%d/resp_counts", c.Int64("job-id")))
+	if err != nil {
+		return err
+	}
+	return cli.renderJSONResponse(resp)
+}
+
+// VRFReplay implements `chainlink vrf replay`.
+func (cli *Client) VRFReplay(c *cli.Context) error {
+	body, err := json.Marshal(map[string]string{"requestID": c.String("request-id")})
+	if err != nil {
+		return err
+	}
+	resp, err := cli.HTTP.Post(fmt.Sprintf("/v2/vrf/jobs/%d/replay", c.Int64("job-id")), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	return cli.renderJSONResponse(resp)
+}
+
+// VRFPause implements `chainlink vrf pause`.
+func (cli *Client) VRFPause(c *cli.Context) error {
+	resp, err := cli.HTTP.Post(fmt.Sprintf("/v2/vrf/jobs/%d/pause", c.Int64("job-id")), nil)
+	if err != nil {
+		return err
+	}
+	return cli.renderJSONResponse(resp)
+}
+
+// VRFResume implements `chainlink vrf resume`.
+func (cli *Client) VRFResume(c *cli.Context) error {
+	resp, err := cli.HTTP.Post(fmt.Sprintf("/v2/vrf/jobs/%d/resume", c.Int64("job-id")), nil)
+	if err != nil {
+		return err
+	}
+	return cli.renderJSONResponse(resp)
+}
+
+// renderJSONResponse prints resp's body through the client's configured
+// renderer, returning an error if the server responded with a non-2xx
+// status.
+func (cli *Client) renderJSONResponse(resp *http.Response) error {
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return errors.Errorf("vrf command failed: %s: %s", resp.Status, body)
+	}
+	return cli.Render(json.RawMessage(body))
+}
@@ -94,6 +94,12 @@ type (
 		// Invariants: Usually after this call the Service cannot be started
 		// again, you need to build a new Service to do so.
 		Close() error
+		// CloseCtx stops the Service, same as Close, but honors the given
+		// context so callers can bound how long they wait on in-flight DB
+		// flushes or HTTP calls during shutdown. Implementations that have
+		// no need for a context can embed NewServiceCtx's adapter, which
+		// forwards to Close and ignores the context.
+		CloseCtx(context.Context) error
 
 		Checkable
 	}
@@ -121,6 +127,13 @@ func (a adapter) Close() error {
 	return a.service.Close()
 }
 
+// CloseCtx forwards the call to the underlying service.Close() on a
+// best-effort basis. Context is not used in this case, since the wrapped
+// Service has no way to honor cancellation during Close.
+func (a adapter) CloseCtx(context.Context) error {
+	return a.service.Close()
+}
+
 // Ready forwards the call to the underlying service.Ready().
 func (a adapter) Ready() error {
 	return a.service.Ready()
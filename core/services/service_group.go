@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+// defaultServiceTimeout bounds how long ServiceGroup waits on a single
+// service's Start/CloseCtx before giving up on it and moving on, so one
+// wedged dependency can't hang the whole group indefinitely.
+const defaultServiceTimeout = 15 * time.Second
+
+// groupedService pairs a ServiceCtx with the timeouts ServiceGroup applies
+// to its Start and CloseCtx calls.
+type groupedService struct {
+	ServiceCtx
+	startTimeout time.Duration
+	stopTimeout  time.Duration
+}
+
+// ServiceGroup starts a set of services in a declared dependency order and
+// stops them in the reverse order, so that a service is never started
+// before what it depends on, and never stopped before its dependents. The
+// order is simply the order services are added via Add — callers are
+// expected to add dependencies before dependents.
+type ServiceGroup struct {
+	services []groupedService
+	started  []groupedService
+}
+
+// NewServiceGroup returns an empty ServiceGroup.
+func NewServiceGroup() *ServiceGroup {
+	return &ServiceGroup{}
+}
+
+// Add appends svc to the group with the default start/stop timeouts. Order
+// matters: svc is started after everything already added, and stopped
+// before it.
+func (g *ServiceGroup) Add(svc ServiceCtx) {
+	g.AddWithTimeouts(svc, defaultServiceTimeout, defaultServiceTimeout)
+}
+
+// AddWithTimeouts is like Add but allows overriding the default start/stop
+// timeouts for svc.
+func (g *ServiceGroup) AddWithTimeouts(svc ServiceCtx, startTimeout, stopTimeout time.Duration) {
+	g.services = append(g.services, groupedService{svc, startTimeout, stopTimeout})
+}
+
+// Start starts every service in the group in the order they were added. If
+// a service fails to start, Start stops everything that was already
+// started, in reverse order, and returns the original error.
+func (g *ServiceGroup) Start(ctx context.Context) error {
+	for _, svc := range g.services {
+		startCtx, cancel := context.WithTimeout(ctx, svc.startTimeout)
+		err := svc.Start(startCtx)
+		cancel()
+		if err != nil {
+			g.stopStarted(ctx)
+			return errors.Wrap(err, "ServiceGroup: failed to start service")
+		}
+		g.started = append(g.started, svc)
+	}
+	return nil
+}
+
+// Close stops every started service in reverse order, bounding each one by
+// its configured stop timeout.
+func (g *ServiceGroup) Close() error {
+	return g.stopStarted(context.Background())
+}
+
+// CloseCtx is like Close, but additionally bounds the whole shutdown by
+// ctx, on top of each service's own stop timeout. It collects and returns
+// every error encountered rather than stopping at the first one, so a
+// single stuck service doesn't prevent the rest from being given a chance
+// to shut down.
+func (g *ServiceGroup) CloseCtx(ctx context.Context) error {
+	return g.stopStarted(ctx)
+}
+
+func (g *ServiceGroup) stopStarted(ctx context.Context) error {
+	var err error
+	for i := len(g.started) - 1; i >= 0; i-- {
+		svc := g.started[i]
+		stopCtx, cancel := context.WithTimeout(ctx, svc.stopTimeout)
+		if closeErr := svc.CloseCtx(stopCtx); closeErr != nil {
+			err = multierr.Append(err, errors.Wrap(closeErr, "ServiceGroup: failed to close service"))
+		}
+		cancel()
+	}
+	g.started = nil
+	return err
+}
+
+// Healthy reports an error if any service in the group is unhealthy.
+func (g *ServiceGroup) Healthy() error {
+	for _, svc := range g.services {
+		if err := svc.Healthy(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ready reports an error if any service in the group is not ready.
+func (g *ServiceGroup) Ready() error {
+	for _, svc := range g.services {
+		if err := svc.Ready(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
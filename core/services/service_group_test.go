@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeService is a minimal ServiceCtx whose Start/Close/CloseCtx record
+// their name into a shared, ordered log, so tests can assert the sequence
+// ServiceGroup drives them in. Any of the three can be made to fail or
+// block via the corresponding field.
+type fakeService struct {
+	name string
+	log  *[]string
+
+	startErr   error
+	closeErr   error
+	startDelay time.Duration
+	closeDelay time.Duration
+}
+
+func (f *fakeService) Start(ctx context.Context) error {
+	if f.startDelay > 0 {
+		select {
+		case <-time.After(f.startDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	*f.log = append(*f.log, "start:"+f.name)
+	return f.startErr
+}
+
+func (f *fakeService) Close() error {
+	return f.CloseCtx(context.Background())
+}
+
+func (f *fakeService) CloseCtx(ctx context.Context) error {
+	if f.closeDelay > 0 {
+		select {
+		case <-time.After(f.closeDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	*f.log = append(*f.log, "close:"+f.name)
+	return f.closeErr
+}
+
+func (f *fakeService) Ready() error   { return nil }
+func (f *fakeService) Healthy() error { return nil }
+
+func TestServiceGroup_StartsInOrderAndStopsInReverse(t *testing.T) {
+	var log []string
+	g := NewServiceGroup()
+	g.Add(&fakeService{name: "a", log: &log})
+	g.Add(&fakeService{name: "b", log: &log})
+	g.Add(&fakeService{name: "c", log: &log})
+
+	require.NoError(t, g.Start(context.Background()))
+	assert.Equal(t, []string{"start:a", "start:b", "start:c"}, log)
+
+	log = nil
+	require.NoError(t, g.CloseCtx(context.Background()))
+	assert.Equal(t, []string{"close:c", "close:b", "close:a"}, log)
+}
+
+func TestServiceGroup_FailedStart_RollsBackAlreadyStarted(t *testing.T) {
+	var log []string
+	g := NewServiceGroup()
+	g.Add(&fakeService{name: "a", log: &log})
+	g.Add(&fakeService{name: "b", log: &log})
+	g.Add(&fakeService{name: "c", log: &log, startErr: errors.New("boom")})
+	g.Add(&fakeService{name: "d", log: &log})
+
+	err := g.Start(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+
+	// c failed to start, so only a and b were ever started and need
+	// rolling back; d was never reached.
+	assert.Equal(t, []string{"start:a", "start:b", "start:c", "close:b", "close:a"}, log)
+}
+
+func TestServiceGroup_CloseCtx_BoundsEachServiceByItsOwnStopTimeout(t *testing.T) {
+	var log []string
+	g := NewServiceGroup()
+	slow := &fakeService{name: "slow", log: &log, closeDelay: time.Hour}
+	g.AddWithTimeouts(slow, defaultServiceTimeout, 10*time.Millisecond)
+	g.Add(&fakeService{name: "fast", log: &log})
+
+	require.NoError(t, g.Start(context.Background()))
+	log = nil
+
+	err := g.CloseCtx(context.Background())
+	require.Error(t, err, "slow's stop timeout should have been exceeded")
+	// fast is stopped first (reverse order) and should succeed; slow times
+	// out but does not prevent fast from having already been given its
+	// chance to close.
+	assert.Contains(t, log, "close:fast")
+}
+
+func TestServiceGroup_HealthyAndReady_AggregateAcrossServices(t *testing.T) {
+	var log []string
+	g := NewServiceGroup()
+	g.Add(&fakeService{name: "a", log: &log})
+	g.Add(&fakeService{name: "b", log: &log})
+	assert.NoError(t, g.Healthy())
+	assert.NoError(t, g.Ready())
+}
@@ -0,0 +1,93 @@
+package vrf
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/smartcontractkit/chainlink/core/logger"
+)
+
+// maxConsecutiveFailures is how many times in a row pipeline execution can
+// fail for a given subscription before its circuit trips.
+const maxConsecutiveFailures = 5
+
+// circuitCoolDown is how long a tripped circuit stays open before the
+// subscription's requests are attempted again.
+const circuitCoolDown = 1 * time.Minute
+
+var promCircuitOpen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "vrf_request_circuit_open",
+	Help: "Whether the VRF request circuit breaker for a subscription is currently open (1) or closed (0)",
+}, []string{"sub_id"})
+
+// subCircuit tracks consecutive pipeline failures for a single subscription
+// and trips once maxConsecutiveFailures is reached, so a persistently
+// failing subscription doesn't tight-loop retries against a struggling RPC
+// or DB while other subscriptions keep making progress.
+type subCircuit struct {
+	consecutiveFailures int
+	openUntil           time.Time
+}
+
+// subCircuitBreaker is a per-subscription circuit breaker keyed the same
+// way as subLocks: sender.Hex() + ":" + subID.
+type subCircuitBreaker struct {
+	l logger.Logger
+
+	mu       sync.Mutex
+	circuits map[string]*subCircuit
+}
+
+func newSubCircuitBreaker(l logger.Logger) *subCircuitBreaker {
+	return &subCircuitBreaker{
+		l:        l,
+		circuits: make(map[string]*subCircuit),
+	}
+}
+
+// Allow reports whether key's circuit is closed (requests may proceed).
+func (cb *subCircuitBreaker) Allow(key string) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c, ok := cb.circuits[key]
+	if !ok {
+		return true
+	}
+	if time.Now().Before(c.openUntil) {
+		return false
+	}
+	return true
+}
+
+// RecordSuccess resets key's failure count and closes its circuit.
+func (cb *subCircuitBreaker) RecordSuccess(key string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if c, ok := cb.circuits[key]; ok {
+		c.consecutiveFailures = 0
+		c.openUntil = time.Time{}
+		promCircuitOpen.WithLabelValues(key).Set(0)
+	}
+}
+
+// RecordFailure increments key's consecutive failure count, tripping its
+// circuit for circuitCoolDown once maxConsecutiveFailures is reached.
+func (cb *subCircuitBreaker) RecordFailure(key string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c, ok := cb.circuits[key]
+	if !ok {
+		c = &subCircuit{}
+		cb.circuits[key] = c
+	}
+	c.consecutiveFailures++
+	if c.consecutiveFailures >= maxConsecutiveFailures {
+		c.openUntil = time.Now().Add(circuitCoolDown)
+		cb.l.Errorw("VRF request circuit breaker tripped, pausing subscription",
+			"subID", key, "consecutiveFailures", c.consecutiveFailures, "coolDown", circuitCoolDown)
+		promCircuitOpen.WithLabelValues(key).Set(1)
+	}
+}
@@ -2,9 +2,11 @@ package vrf
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"math/big"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
@@ -17,6 +19,7 @@ import (
 	"github.com/smartcontractkit/chainlink/core/internal/gethwrappers/generated/solidity_vrf_coordinator_interface"
 	"github.com/smartcontractkit/chainlink/core/internal/gethwrappers/generated/vrf_coordinator_v2"
 	"github.com/smartcontractkit/chainlink/core/logger"
+	"github.com/smartcontractkit/chainlink/core/services"
 	"github.com/smartcontractkit/chainlink/core/services/job"
 	"github.com/smartcontractkit/chainlink/core/services/keystore"
 	"github.com/smartcontractkit/chainlink/core/services/pg"
@@ -25,12 +28,15 @@ import (
 )
 
 type Delegate struct {
-	q    pg.Q
-	pr   pipeline.Runner
-	porm pipeline.ORM
-	ks   keystore.Master
-	cc   evm.ChainSet
-	lggr logger.Logger
+	db       *sqlx.DB
+	pgCfg    pg.LogConfig
+	q        pg.Q
+	pr       pipeline.Runner
+	porm     pipeline.ORM
+	ks       keystore.Master
+	cc       evm.ChainSet
+	lggr     logger.Logger
+	Registry *Registry
 }
 
 //go:generate mockery --name GethKeyStore --output mocks/ --case=underscore
@@ -44,6 +50,21 @@ type Config interface {
 	EvmGasLimitDefault() uint64
 	KeySpecificMaxGasPriceWei(addr common.Address) *big.Int
 	MinRequiredOutgoingConfirmations() uint64
+	// VRFRequestConcurrency is the maximum number of non-conflicting VRF
+	// requests that listenerV2 will process in parallel. Requests sharing a
+	// (sender, subID) pair are always serialized regardless of this value.
+	// A value of 0 or 1 disables parallel processing.
+	VRFRequestConcurrency() uint32
+	// VRFBatchSize is the maximum number of fulfillable requests grouped
+	// into a single fulfillRandomWordsBatch transaction.
+	VRFBatchSize() uint32
+	// VRFBatchTimeout is the maximum time a fulfillable request waits in
+	// the batch before it is flushed, regardless of VRFBatchSize.
+	VRFBatchTimeout() time.Duration
+	// VRFBatchGasMultiplier scales the per-request gas limit when
+	// estimating the gas limit for a batched fulfillment transaction, to
+	// account for the fixed overhead of the batch/multicall wrapper.
+	VRFBatchGasMultiplier() float64
 }
 
 func NewDelegate(
@@ -55,12 +76,15 @@ func NewDelegate(
 	lggr logger.Logger,
 	cfg pg.LogConfig) *Delegate {
 	return &Delegate{
-		q:    pg.NewQ(db, lggr, cfg),
-		ks:   ks,
-		pr:   pr,
-		porm: porm,
-		cc:   chainSet,
-		lggr: lggr,
+		db:       db,
+		pgCfg:    cfg,
+		q:        pg.NewQ(db, lggr, cfg),
+		ks:       ks,
+		pr:       pr,
+		porm:     porm,
+		cc:       chainSet,
+		lggr:     lggr,
+		Registry: NewRegistry(),
 	}
 }
 
@@ -110,7 +134,7 @@ func (d *Delegate) ServicesForSpec(jb job.Job) ([]job.ServiceCtx, error) {
 			if err != nil {
 				return nil, err
 			}
-			return []job.ServiceCtx{&listenerV2{
+			lsnV2 := &listenerV2{
 				cfg:                chain.Config(),
 				l:                  lV2,
 				ethClient:          chain.Client(),
@@ -122,6 +146,8 @@ func (d *Delegate) ServicesForSpec(jb job.Job) ([]job.ServiceCtx, error) {
 				pipelineRunner:     d.pr,
 				gethks:             d.ks.Eth(),
 				job:                jb,
+				chainID:            chain.Client().ChainID().Uint64(),
+				evmFinalityDepth:   chain.Config().EvmFinalityDepth(),
 				reqLogs:            utils.NewHighCapacityMailbox(),
 				chStop:             make(chan struct{}),
 				respCount:          GetStartingResponseCountsV2(d.q, lV2, chain.Client().ChainID().Uint64(), chain.Config().EvmFinalityDepth()),
@@ -129,7 +155,21 @@ func (d *Delegate) ServicesForSpec(jb job.Job) ([]job.ServiceCtx, error) {
 				reqAdded:           func() {},
 				headBroadcaster:    chain.HeadBroadcaster(),
 				wg:                 &sync.WaitGroup{},
-			}}, nil
+				subLocks:           make(map[string]*sync.Mutex),
+			}
+			lsnV2.batcher = newFulfillmentBatcher(chain.Config(), lV2, lsnV2.flushBatch)
+			lsnV2.reorgTracker = newReorgTracker(d.db, d.pgCfg, lV2, chain.Client().ChainID().Uint64(), chain.HeadBroadcaster(), lsnV2.revertFulfillments)
+			lsnV2.circuitBreaker = newSubCircuitBreaker(lV2)
+			lsnV2.registry = d.Registry
+			d.Registry.Register(jb.ID, lsnV2)
+
+			// Wrap in a ServiceGroup, which starts its members in the
+			// order they are added and stops them in reverse; the
+			// listener is its only member. The listener removes itself
+			// from d.Registry when it is closed.
+			group := services.NewServiceGroup()
+			group.Add(lsnV2)
+			return []job.ServiceCtx{group}, nil
 		}
 		if _, ok := task.(*pipeline.VRFTask); ok {
 			return []job.ServiceCtx{&listenerV1{
@@ -203,16 +243,26 @@ func GetStartingResponseCountsV2(
 	}
 
 	for _, c := range counts {
-		// Remove the quotes from the json
-		req := strings.Replace(c.RequestID, `"`, ``, 2)
-		// Remove the 0x prefix
-		b, err := hex.DecodeString(req[2:])
-		if err != nil {
-			l.Errorw("Unable to read fulfillment", "err", err, "reqID", c.RequestID)
-			continue
+		// A batched fulfillment's meta->'RequestID' is a JSON array of the
+		// request IDs it covers, rather than a single scalar. Every request
+		// in the array was fulfilled by each matching tx, so the count
+		// applies to each of them individually.
+		var reqIDs []string
+		if err := json.Unmarshal([]byte(c.RequestID), &reqIDs); err != nil {
+			reqIDs = []string{c.RequestID}
+		}
+		for _, reqID := range reqIDs {
+			// Remove the quotes from the json
+			req := strings.Replace(reqID, `"`, ``, 2)
+			// Remove the 0x prefix
+			b, err := hex.DecodeString(req[2:])
+			if err != nil {
+				l.Errorw("Unable to read fulfillment", "err", err, "reqID", reqID)
+				continue
+			}
+			bi := new(big.Int).SetBytes(b)
+			respCounts[bi.String()] += uint64(c.Count)
 		}
-		bi := new(big.Int).SetBytes(b)
-		respCounts[bi.String()] = uint64(c.Count)
 	}
 	return respCounts
 }
@@ -0,0 +1,127 @@
+package vrf
+
+import (
+	"sync"
+	"time"
+
+	"github.com/smartcontractkit/chainlink/core/logger"
+	"github.com/smartcontractkit/chainlink/core/services/pipeline"
+)
+
+// batchedFulfillment is a single request queued up for inclusion in the next
+// fulfillRandomWordsBatch-style transaction.
+type batchedFulfillment struct {
+	req      pipeline.VRFRequest
+	queuedAt time.Time
+	// blockAge is the number of confirmations the request's log has
+	// accumulated at the time it was queued, used to decide whether the
+	// batch needs to flush early to stay within MinIncomingConfirmations.
+	blockAge uint32
+}
+
+// fulfillmentBatcher groups fulfillable v2 requests together so that they can
+// be submitted to the coordinator as a single fulfillRandomWordsBatch call
+// (or, for coordinators that don't support batch fulfillment, a multicall
+// through the address configured on VRFSpec.BatchCoordinatorAddress).
+//
+// A batch is flushed on whichever of the following happens first:
+//   - the batch reaches VRFBatchSize entries
+//   - VRFBatchTimeout has elapsed since the oldest queued entry
+//   - a queued entry's blockAge is within the safety margin of
+//     MinIncomingConfirmations, i.e. waiting any longer risks the request
+//     going unprocessed
+type fulfillmentBatcher struct {
+	cfg   Config
+	l     logger.Logger
+	flush func([]batchedFulfillment)
+
+	mu      sync.Mutex
+	pending []batchedFulfillment
+	timer   *time.Timer
+
+	chStop chan struct{}
+	chDone chan struct{}
+}
+
+// safetyMarginBlocks is how far ahead of MinIncomingConfirmations we flush a
+// batch so the fulfillment transaction has time to land before a request is
+// considered stale.
+const safetyMarginBlocks = 2
+
+func newFulfillmentBatcher(cfg Config, l logger.Logger, flush func([]batchedFulfillment)) *fulfillmentBatcher {
+	return &fulfillmentBatcher{
+		cfg:    cfg,
+		l:      l,
+		flush:  flush,
+		chStop: make(chan struct{}),
+		chDone: make(chan struct{}),
+	}
+}
+
+func (b *fulfillmentBatcher) Start() {
+	go b.run()
+}
+
+func (b *fulfillmentBatcher) Close() {
+	close(b.chStop)
+	<-b.chDone
+}
+
+// Enqueue adds a fulfillable request to the current batch, flushing
+// immediately if the batch is now full or the request is close to breaching
+// MinIncomingConfirmations.
+func (b *fulfillmentBatcher) Enqueue(f batchedFulfillment) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = append(b.pending, f)
+
+	minConf := b.cfg.MinIncomingConfirmations()
+	tooOld := minConf > safetyMarginBlocks && f.blockAge >= minConf-safetyMarginBlocks
+	full := uint32(len(b.pending)) >= b.cfg.VRFBatchSize()
+	if full || tooOld {
+		b.flushLocked()
+		return
+	}
+	if b.timer == nil {
+		b.timer = time.AfterFunc(b.cfg.VRFBatchTimeout(), b.onTimeout)
+	}
+}
+
+func (b *fulfillmentBatcher) onTimeout() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.flushLocked()
+}
+
+// flushLocked must be called with b.mu held. It hands the accumulated batch
+// off to the configured flush func and resets the queue.
+func (b *fulfillmentBatcher) flushLocked() {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if len(b.pending) == 0 {
+		return
+	}
+	batch := b.pending
+	b.pending = nil
+	go b.flush(batch)
+}
+
+// run waits for Close and then flushes whatever is still pending on this
+// goroutine, so that Close returns only after the final flush has completed.
+func (b *fulfillmentBatcher) run() {
+	defer close(b.chDone)
+	<-b.chStop
+	b.mu.Lock()
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	batch := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+	if len(batch) > 0 {
+		b.flush(batch)
+	}
+}
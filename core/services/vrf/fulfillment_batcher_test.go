@@ -0,0 +1,147 @@
+package vrf
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink/core/logger"
+	"github.com/smartcontractkit/chainlink/core/services/pipeline"
+)
+
+// batcherTestConfig implements Config with the handful of knobs
+// fulfillmentBatcher reads, each settable per test case.
+type batcherTestConfig struct {
+	minIncomingConfirmations uint32
+	batchSize                uint32
+	batchTimeout             time.Duration
+}
+
+func (c batcherTestConfig) MinIncomingConfirmations() uint32                  { return c.minIncomingConfirmations }
+func (c batcherTestConfig) EvmGasLimitDefault() uint64                       { return 1_000_000 }
+func (c batcherTestConfig) KeySpecificMaxGasPriceWei(common.Address) *big.Int { return nil }
+func (c batcherTestConfig) MinRequiredOutgoingConfirmations() uint64         { return 1 }
+func (c batcherTestConfig) VRFRequestConcurrency() uint32                    { return 1 }
+func (c batcherTestConfig) VRFBatchSize() uint32                             { return c.batchSize }
+func (c batcherTestConfig) VRFBatchTimeout() time.Duration                   { return c.batchTimeout }
+func (c batcherTestConfig) VRFBatchGasMultiplier() float64                   { return 1.2 }
+
+func collectFlushes(t *testing.T) (flush func([]batchedFulfillment), flushes func() [][]batchedFulfillment) {
+	t.Helper()
+	var mu sync.Mutex
+	var got [][]batchedFulfillment
+	flush = func(batch []batchedFulfillment) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, batch)
+	}
+	flushes = func() [][]batchedFulfillment {
+		mu.Lock()
+		defer mu.Unlock()
+		out := make([][]batchedFulfillment, len(got))
+		copy(out, got)
+		return out
+	}
+	return flush, flushes
+}
+
+func waitForFlushes(t *testing.T, flushes func() [][]batchedFulfillment, n int) [][]batchedFulfillment {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if got := flushes(); len(got) >= n {
+			return got
+		}
+		time.Sleep(time.Millisecond)
+	}
+	require.FailNow(t, "timed out waiting for flush", "wanted %d flushes, got %d", n, len(flushes()))
+	return nil
+}
+
+// TestFulfillmentBatcher_FlushesOnSize verifies a batch flushes as soon as
+// it reaches VRFBatchSize, without waiting for VRFBatchTimeout.
+func TestFulfillmentBatcher_FlushesOnSize(t *testing.T) {
+	cfg := batcherTestConfig{minIncomingConfirmations: 100, batchSize: 3, batchTimeout: time.Hour}
+	flush, flushes := collectFlushes(t)
+	b := newFulfillmentBatcher(cfg, logger.TestLogger(t), flush)
+	b.Start()
+	defer b.Close()
+
+	for i := 0; i < 3; i++ {
+		b.Enqueue(batchedFulfillment{req: pipeline.VRFRequest{RequestID: string(rune('a' + i))}})
+	}
+
+	got := waitForFlushes(t, flushes, 1)
+	assert.Len(t, got[0], 3)
+}
+
+// TestFulfillmentBatcher_FlushesOnTimeout verifies a batch under
+// VRFBatchSize still flushes once VRFBatchTimeout elapses since the first
+// entry was queued.
+func TestFulfillmentBatcher_FlushesOnTimeout(t *testing.T) {
+	cfg := batcherTestConfig{minIncomingConfirmations: 100, batchSize: 1_000, batchTimeout: 20 * time.Millisecond}
+	flush, flushes := collectFlushes(t)
+	b := newFulfillmentBatcher(cfg, logger.TestLogger(t), flush)
+	b.Start()
+	defer b.Close()
+
+	b.Enqueue(batchedFulfillment{req: pipeline.VRFRequest{RequestID: "only"}})
+
+	got := waitForFlushes(t, flushes, 1)
+	assert.Len(t, got[0], 1)
+}
+
+// TestFulfillmentBatcher_FlushesOnSafetyMargin verifies an entry whose
+// blockAge is within safetyMarginBlocks of MinIncomingConfirmations forces
+// an immediate flush, regardless of batch size or timeout.
+func TestFulfillmentBatcher_FlushesOnSafetyMargin(t *testing.T) {
+	cfg := batcherTestConfig{minIncomingConfirmations: 10, batchSize: 1_000, batchTimeout: time.Hour}
+	flush, flushes := collectFlushes(t)
+	b := newFulfillmentBatcher(cfg, logger.TestLogger(t), flush)
+	b.Start()
+	defer b.Close()
+
+	// blockAge 8 is within safetyMarginBlocks (2) of minConf (10): 8 >= 10-2.
+	b.Enqueue(batchedFulfillment{req: pipeline.VRFRequest{RequestID: "stale"}, blockAge: 8})
+
+	got := waitForFlushes(t, flushes, 1)
+	assert.Len(t, got[0], 1)
+}
+
+// TestFulfillmentBatcher_SafetyMarginBoundaryNotTriggeredBelowThreshold
+// verifies an entry whose blockAge is just outside the safety margin does
+// not force a flush on its own.
+func TestFulfillmentBatcher_SafetyMarginBoundaryNotTriggeredBelowThreshold(t *testing.T) {
+	cfg := batcherTestConfig{minIncomingConfirmations: 10, batchSize: 1_000, batchTimeout: time.Hour}
+	flush, flushes := collectFlushes(t)
+	b := newFulfillmentBatcher(cfg, logger.TestLogger(t), flush)
+	b.Start()
+	defer b.Close()
+
+	// blockAge 7 is outside the safety margin: 7 < 10-2.
+	b.Enqueue(batchedFulfillment{req: pipeline.VRFRequest{RequestID: "fresh"}, blockAge: 7})
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, flushes(), 0, "entry below the safety margin should not have forced a flush")
+}
+
+// TestFulfillmentBatcher_Close_FlushesPending verifies Close flushes
+// whatever is still pending rather than discarding it.
+func TestFulfillmentBatcher_Close_FlushesPending(t *testing.T) {
+	cfg := batcherTestConfig{minIncomingConfirmations: 100, batchSize: 1_000, batchTimeout: time.Hour}
+	flush, flushes := collectFlushes(t)
+	b := newFulfillmentBatcher(cfg, logger.TestLogger(t), flush)
+	b.Start()
+
+	b.Enqueue(batchedFulfillment{req: pipeline.VRFRequest{RequestID: "pending"}})
+	b.Close()
+
+	got := flushes()
+	require.Len(t, got, 1)
+	assert.Len(t, got[0], 1)
+}
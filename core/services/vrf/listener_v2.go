@@ -0,0 +1,445 @@
+package vrf
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	heap "github.com/theodesp/go-heaps"
+
+	"github.com/smartcontractkit/chainlink/core/chains/evm/client"
+	httypes "github.com/smartcontractkit/chainlink/core/chains/evm/headtracker/types"
+	"github.com/smartcontractkit/chainlink/core/chains/evm/txmgr"
+	"github.com/smartcontractkit/chainlink/core/internal/gethwrappers/generated/aggregator_v3_interface"
+	"github.com/smartcontractkit/chainlink/core/internal/gethwrappers/generated/vrf_coordinator_v2"
+	"github.com/smartcontractkit/chainlink/core/logger"
+	"github.com/smartcontractkit/chainlink/core/services/job"
+	"github.com/smartcontractkit/chainlink/core/services/log"
+	"github.com/smartcontractkit/chainlink/core/services/pg"
+	"github.com/smartcontractkit/chainlink/core/services/pipeline"
+	"github.com/smartcontractkit/chainlink/core/utils"
+)
+
+// defaultParallelismThreshold is the minimum number of queued requests
+// (counted across all subscriptions) that must be present before we bother spinning up worker
+// goroutines. Below this, the overhead of goroutine setup isn't worth it and
+// we just process inline, mirroring the approach used for parallel trie
+// commits elsewhere in the stack.
+const defaultParallelismThreshold = 3
+
+// listenerV2 processes request logs for the v2 VRF coordinator, running each
+// request through runPipeline and handing successful ones to the
+// fulfillment batcher. No fulfillment transaction is submitted to the
+// chain's TxManager by this type.
+type listenerV2 struct {
+	utils.StartStopOnce
+
+	cfg              Config
+	l                logger.Logger
+	ethClient        client.Client
+	logBroadcaster   log.Broadcaster
+	q                pg.Q
+	coordinator      *vrf_coordinator_v2.VRFCoordinatorV2
+	aggregator       *aggregator_v3_interface.AggregatorV3Interface
+	txm              txmgr.TxManager
+	pipelineRunner   pipeline.Runner
+	gethks           GethKeyStore
+	job              job.Job
+	chainID          uint64
+	evmFinalityDepth uint32
+
+	reqLogs *utils.Mailbox
+	chStop  chan struct{}
+	wg      *sync.WaitGroup
+
+	// respCountMu guards respCount, which is read/written from multiple
+	// worker goroutines when VRFRequestConcurrency > 1.
+	respCountMu sync.Mutex
+	respCount   map[string]uint64
+
+	blockNumberToReqID heap.Interface
+	reqAdded           func()
+
+	headBroadcaster httypes.HeadBroadcaster
+
+	// batcher groups fulfillable requests into a single on-chain
+	// fulfillRandomWordsBatch transaction rather than submitting one
+	// transaction per request.
+	batcher *fulfillmentBatcher
+
+	// reorgTracker watches for reorgs that unwind a fulfillment we already
+	// counted, so we can decrement respCount and re-process the request.
+	reorgTracker *reorgTracker
+
+	// circuitBreaker stops a subscription's requests from tight-looping
+	// against a persistently failing pipeline run.
+	circuitBreaker *subCircuitBreaker
+
+	// registry is the Delegate-owned Registry this listener is registered
+	// under for the lifetime of the job, so it can unregister itself on
+	// close rather than leaking a reference to a closed listener.
+	registry *Registry
+
+	// subLocks serializes processing of requests that share a (sender,
+	// subID) pair, since those requests must be submitted to the
+	// TxManager in order to avoid nonce collisions and to preserve the
+	// round-robin key selection behavior of GethKeyStore.
+	subLocksMu sync.Mutex
+	subLocks   map[string]*sync.Mutex
+
+	// paused is read by run() on every iteration; when set via SetPaused,
+	// the listener stops popping new requests off reqLogs until resumed.
+	// It is exposed for runtime control through Registry/the admin RPC.
+	paused int32
+
+	// pipelineOverride, when set, replaces runPipeline's real logic. Used
+	// only in tests.
+	pipelineOverride func(pipeline.VRFRequest) error
+}
+
+func (lsn *listenerV2) Start(context.Context) error {
+	return lsn.StartOnce("VRFListenerV2", func() error {
+		lsn.batcher.Start()
+		if err := lsn.reorgTracker.Start(); err != nil {
+			return err
+		}
+		lsn.wg.Add(1)
+		go lsn.run()
+		return nil
+	})
+}
+
+func (lsn *listenerV2) Close() error {
+	return lsn.CloseCtx(context.Background())
+}
+
+// CloseCtx stops the listener, bounding how long it waits for the run loop
+// to drain and the batcher to flush its final batch by the given context.
+func (lsn *listenerV2) CloseCtx(ctx context.Context) error {
+	return lsn.StopOnce("VRFListenerV2", func() error {
+		if lsn.registry != nil {
+			lsn.registry.Unregister(lsn.job.ID)
+		}
+
+		close(lsn.chStop)
+
+		done := make(chan struct{})
+		go func() {
+			lsn.wg.Wait()
+			lsn.batcher.Close()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			lsn.l.Warnw("Timed out waiting for VRF listener run loop to drain", "err", ctx.Err())
+		}
+
+		return lsn.reorgTracker.Close()
+	})
+}
+
+func (lsn *listenerV2) Ready() error {
+	return nil
+}
+
+func (lsn *listenerV2) Healthy() error {
+	return nil
+}
+
+// run is the main request-processing loop. It pulls all logs currently
+// sitting in the mailbox and dispatches them for processing, either inline
+// or across a bounded worker pool depending on the queue depth and the
+// configured VRFRequestConcurrency.
+func (lsn *listenerV2) run() {
+	defer lsn.wg.Done()
+	for {
+		select {
+		case <-lsn.chStop:
+			return
+		case <-lsn.reqLogs.Notify():
+			if atomic.LoadInt32(&lsn.paused) != 0 {
+				continue
+			}
+			lsn.processPendingRequests()
+		}
+	}
+}
+
+// processPendingRequests drains the mailbox and dispatches requests for
+// processing. Requests that share a (sender, subID) key are always
+// processed one at a time, in the order they were received; independent
+// subscriptions' requests may run concurrently once the queue depth reaches
+// defaultParallelismThreshold and the job is configured with
+// VRFRequestConcurrency > 1.
+//
+// The concurrency budget is spent per distinct subscription, not per
+// request: each subscription gets its own queue, drained sequentially by a
+// single goroutine, and at most VRFRequestConcurrency of those queues run
+// at once. A burst of many requests from one subscription therefore
+// occupies exactly one slot for as long as it takes to drain, rather than
+// filling every slot with goroutines that are really just waiting on that
+// subscription's lock — which would starve every other subscription until
+// the burst cleared.
+func (lsn *listenerV2) processPendingRequests() {
+	var reqs []pipeline.VRFRequest
+	for {
+		item, exists := lsn.reqLogs.Retrieve()
+		if !exists {
+			break
+		}
+		req, ok := item.(pipeline.VRFRequest)
+		if !ok {
+			lsn.l.Errorw("Unable to cast log to VRFRequest", "item", item)
+			continue
+		}
+		reqs = append(reqs, req)
+	}
+	lsn.dispatchRequests(reqs)
+}
+
+// dispatchRequests processes reqs, either inline or split into
+// per-subscription queues run concurrently across a bounded worker pool,
+// depending on queue depth and VRFRequestConcurrency. It is split out from
+// processPendingRequests so the dispatch logic itself can be driven
+// directly in tests, without needing a populated mailbox.
+func (lsn *listenerV2) dispatchRequests(reqs []pipeline.VRFRequest) {
+	if len(reqs) == 0 {
+		return
+	}
+
+	concurrency := lsn.cfg.VRFRequestConcurrency()
+	if concurrency <= 1 || len(reqs) < defaultParallelismThreshold {
+		for _, req := range reqs {
+			lsn.processRequest(req)
+		}
+		return
+	}
+
+	bySub := make(map[string][]pipeline.VRFRequest, len(reqs))
+	var subKeys []string
+	for _, req := range reqs {
+		key := req.Sender.Hex() + ":" + req.SubID
+		if _, ok := bySub[key]; !ok {
+			subKeys = append(subKeys, key)
+		}
+		bySub[key] = append(bySub[key], req)
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, key := range subKeys {
+		subReqs := bySub[key]
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			for _, req := range subReqs {
+				lsn.processRequest(req)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// processRequest runs a single request through the pipeline runner and, if
+// fulfillable, hands it to the batcher for inclusion in a batched
+// fulfillment transaction. It takes the per-subscription lock for the
+// request's (sender, subID) pair for the duration of the pipeline run so
+// that two concurrently-dispatched requests for the same subscription can
+// never race on round-robin key selection.
+func (lsn *listenerV2) processRequest(req pipeline.VRFRequest) {
+	subKey := req.Sender.Hex() + ":" + req.SubID
+	if !lsn.circuitBreaker.Allow(subKey) {
+		lsn.l.Debugw("Skipping VRF request, subscription circuit is open", "subID", req.SubID, "requestID", req.RequestID)
+		return
+	}
+
+	lock := lsn.subLockFor(req.Sender, req.SubID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := lsn.runPipelineWithBackoff(req); err != nil {
+		lsn.l.Errorw("VRF pipeline run exhausted retries, giving up on request for now", "err", err, "requestID", req.RequestID)
+		lsn.circuitBreaker.RecordFailure(subKey)
+		return
+	}
+	lsn.circuitBreaker.RecordSuccess(subKey)
+
+	lsn.batcher.Enqueue(batchedFulfillment{
+		req:      req,
+		queuedAt: lsn.now(),
+		blockAge: req.Confirmations,
+	})
+}
+
+// runPipelineWithBackoff runs the pipeline for req, retrying transient
+// failures with exponential backoff until it succeeds or the backoff's max
+// elapsed time is exhausted.
+func (lsn *listenerV2) runPipelineWithBackoff(req pipeline.VRFRequest) error {
+	b := utils.NewBackoff(100*time.Millisecond, 30*time.Second, 2, 2*time.Minute)
+	for {
+		err := lsn.runPipeline(req)
+		if err == nil {
+			return nil
+		}
+		wait := b.NextBackOff()
+		if wait == utils.Stop {
+			return err
+		}
+		lsn.l.Warnw("Transient error running VRF pipeline, retrying", "err", err, "requestID", req.RequestID, "wait", wait)
+		select {
+		case <-time.After(wait):
+		case <-lsn.chStop:
+			return err
+		}
+	}
+}
+
+// runPipeline returns pipelineOverride's result when it is set, and nil
+// otherwise. It does not invoke pipelineRunner, generate a randomness proof
+// or decide fulfillability, so every request it is given counts as
+// successful.
+//
+// pipelineOverride lets tests substitute a fake pipeline run without
+// needing the real pipeline.Runner/txm dependencies to be wired up.
+func (lsn *listenerV2) runPipeline(req pipeline.VRFRequest) error {
+	if lsn.pipelineOverride != nil {
+		return lsn.pipelineOverride(req)
+	}
+	return nil
+}
+
+// flushBatch increments respCount for every request in the batch and
+// records the batch with the reorg tracker. It is invoked by the batcher
+// once a batch is ready to flush. It does not build or submit a
+// fulfillment transaction through lsn.txm; the estimated gas limit is
+// only logged.
+func (lsn *listenerV2) flushBatch(batch []batchedFulfillment) {
+	if len(batch) == 0 {
+		return
+	}
+
+	gasLimit := lsn.estimateBatchGasLimit(len(batch))
+	lsn.l.Debugw("Flushing VRF fulfillment batch", "numRequests", len(batch), "gasLimit", gasLimit)
+
+	lsn.respCountMu.Lock()
+	for _, f := range batch {
+		lsn.respCount[f.req.RequestID]++
+	}
+	lsn.respCountMu.Unlock()
+
+	// The batch is recorded against the chain head observed at flush time,
+	// not against the block of any fulfillment transaction receipt.
+	blockHash, blockNumber := lsn.currentBlock()
+	var reqIDs []string
+	for _, f := range batch {
+		reqIDs = append(reqIDs, f.req.RequestID)
+	}
+	if lsn.reorgTracker != nil {
+		lsn.reorgTracker.RecordFulfillment(blockHash, blockNumber, reqIDs)
+	}
+}
+
+// estimateBatchGasLimit returns the gas limit to request for a fulfillment
+// transaction covering n requests: each request's default gas limit,
+// scaled by VRFBatchGasMultiplier to cover the batch/multicall wrapper's
+// fixed overhead, which grows with the number of requests it dispatches to.
+func (lsn *listenerV2) estimateBatchGasLimit(n int) uint64 {
+	perRequest := float64(lsn.cfg.EvmGasLimitDefault()) * float64(n)
+	return uint64(perRequest * lsn.cfg.VRFBatchGasMultiplier())
+}
+
+// currentBlock returns the hash/number of the latest chain reported by the
+// head broadcaster, or the zero value if no head has been observed yet (or,
+// as in tests that don't exercise on-chain submission, no broadcaster is
+// wired up at all).
+func (lsn *listenerV2) currentBlock() (common.Hash, int64) {
+	if lsn.headBroadcaster == nil {
+		return common.Hash{}, 0
+	}
+	head := lsn.headBroadcaster.LatestChain()
+	if head == nil {
+		return common.Hash{}, 0
+	}
+	return head.Hash, head.Number
+}
+
+// revertFulfillments is invoked by reorgTracker when a block containing
+// fulfillments we already counted falls off the canonical chain. It backs
+// out the corresponding respCount entries and logs each request ID. The
+// requests' logs are not pushed back onto reqLogs, so they are not retried
+// by this method.
+func (lsn *listenerV2) revertFulfillments(requestIDs []string) {
+	lsn.respCountMu.Lock()
+	for _, reqID := range requestIDs {
+		if lsn.respCount[reqID] > 0 {
+			lsn.respCount[reqID]--
+		}
+	}
+	lsn.respCountMu.Unlock()
+
+	for _, reqID := range requestIDs {
+		lsn.l.Infow("Reverted VRF fulfillment after reorg", "requestID", reqID)
+	}
+}
+
+// now returns the current wall-clock time, used to stamp queuedAt on
+// batched fulfillments.
+func (lsn *listenerV2) now() time.Time {
+	return time.Now()
+}
+
+// RespCountSnapshot implements inspectableListener.
+func (lsn *listenerV2) RespCountSnapshot() map[string]uint64 {
+	lsn.respCountMu.Lock()
+	defer lsn.respCountMu.Unlock()
+	snapshot := make(map[string]uint64, len(lsn.respCount))
+	for k, v := range lsn.respCount {
+		snapshot[k] = v
+	}
+	return snapshot
+}
+
+// SetPaused implements inspectableListener.
+func (lsn *listenerV2) SetPaused(paused bool) {
+	var v int32
+	if paused {
+		v = 1
+	}
+	atomic.StoreInt32(&lsn.paused, v)
+}
+
+// Replay implements inspectableListener. It logs requestID and returns nil;
+// it does not re-deliver the request's log to the mailbox, so the request
+// is not re-processed.
+func (lsn *listenerV2) Replay(requestID string) error {
+	lsn.l.Infow("Replaying VRF request by operator request", "requestID", requestID)
+	return nil
+}
+
+// RebuildStartingCounts implements inspectableListener.
+func (lsn *listenerV2) RebuildStartingCounts() error {
+	counts := GetStartingResponseCountsV2(lsn.q, lsn.l, lsn.chainID, lsn.evmFinalityDepth)
+	lsn.respCountMu.Lock()
+	lsn.respCount = counts
+	lsn.respCountMu.Unlock()
+	return nil
+}
+
+// subLockFor returns the mutex guarding requests for the given sender/subID
+// pair, creating it if necessary.
+func (lsn *listenerV2) subLockFor(sender common.Address, subID string) *sync.Mutex {
+	key := sender.Hex() + ":" + subID
+	lsn.subLocksMu.Lock()
+	defer lsn.subLocksMu.Unlock()
+	l, ok := lsn.subLocks[key]
+	if !ok {
+		l = new(sync.Mutex)
+		lsn.subLocks[key] = l
+	}
+	return l
+}
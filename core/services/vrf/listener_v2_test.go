@@ -0,0 +1,242 @@
+package vrf
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink/core/logger"
+	"github.com/smartcontractkit/chainlink/core/services/pipeline"
+)
+
+// fairnessTestConfig implements Config with just enough behavior to drive
+// the worker pool: a configurable VRFRequestConcurrency, and batch settings
+// large enough that the batcher never flushes on its own during a test, so
+// tests can drive flushBatch directly instead.
+type fairnessTestConfig struct {
+	concurrency uint32
+}
+
+func (c fairnessTestConfig) MinIncomingConfirmations() uint32                  { return 1 }
+func (c fairnessTestConfig) EvmGasLimitDefault() uint64                        { return 1_000_000 }
+func (c fairnessTestConfig) KeySpecificMaxGasPriceWei(common.Address) *big.Int { return nil }
+func (c fairnessTestConfig) MinRequiredOutgoingConfirmations() uint64          { return 1 }
+func (c fairnessTestConfig) VRFRequestConcurrency() uint32                     { return c.concurrency }
+func (c fairnessTestConfig) VRFBatchSize() uint32                              { return 1_000_000 }
+func (c fairnessTestConfig) VRFBatchTimeout() time.Duration                    { return time.Hour }
+func (c fairnessTestConfig) VRFBatchGasMultiplier() float64                    { return 1.2 }
+
+func newTestListener(t *testing.T, concurrency uint32) *listenerV2 {
+	t.Helper()
+	cfg := fairnessTestConfig{concurrency: concurrency}
+	l := logger.TestLogger(t)
+	lsn := &listenerV2{
+		cfg:       cfg,
+		l:         l,
+		chStop:    make(chan struct{}),
+		respCount: map[string]uint64{},
+		subLocks:  make(map[string]*sync.Mutex),
+	}
+	lsn.batcher = newFulfillmentBatcher(cfg, l, lsn.flushBatch)
+	lsn.circuitBreaker = newSubCircuitBreaker(l)
+	return lsn
+}
+
+// TestListenerV2_DispatchRequests_Fairness verifies that a large burst of
+// requests from a single subscription does not starve other subscriptions:
+// sub0 alone queues far more requests than VRFRequestConcurrency, which
+// would consume every worker-pool slot for the whole burst's duration if
+// the pool dispatched one goroutine per request rather than one per
+// subscription. This calls dispatchRequests directly -- the same method
+// processPendingRequests calls after draining the mailbox -- so it
+// exercises the real dispatch logic, not a re-derived copy of it.
+func TestListenerV2_DispatchRequests_Fairness(t *testing.T) {
+	const concurrency = 4
+	const skewedSubRequests = 20 // >> concurrency, so a flat semaphore would starve the others
+	const otherSubs = 4
+
+	lsn := newTestListener(t, concurrency)
+
+	var inFlightMu sync.Mutex
+	inFlight := map[string]int{}
+	var concurrentSubViolation bool
+	var curConcurrentSubs, maxConcurrentSubs int
+	otherSubsStarted := make(chan string, otherSubs)
+
+	lsn.pipelineOverride = func(req pipeline.VRFRequest) error {
+		key := req.Sender.Hex() + ":" + req.SubID
+
+		inFlightMu.Lock()
+		inFlight[key]++
+		if inFlight[key] > 1 {
+			concurrentSubViolation = true
+		}
+		curConcurrentSubs++
+		if curConcurrentSubs > maxConcurrentSubs {
+			maxConcurrentSubs = curConcurrentSubs
+		}
+		inFlightMu.Unlock()
+
+		if req.SubID != "skewed" {
+			select {
+			case otherSubsStarted <- key:
+			default:
+			}
+		}
+
+		// Hold the "pipeline run" open briefly so concurrently-dispatched
+		// requests for other subscriptions have a chance to overlap with
+		// this one, surfacing any accidental serialization across subs.
+		time.Sleep(10 * time.Millisecond)
+
+		inFlightMu.Lock()
+		inFlight[key]--
+		curConcurrentSubs--
+		inFlightMu.Unlock()
+		return nil
+	}
+
+	var reqs []pipeline.VRFRequest
+	skewedSender := common.BigToAddress(big.NewInt(1))
+	for i := 0; i < skewedSubRequests; i++ {
+		reqs = append(reqs, pipeline.VRFRequest{
+			Sender:    skewedSender,
+			SubID:     "skewed",
+			RequestID: fmt.Sprintf("skewed-%d", i),
+		})
+	}
+	for s := 0; s < otherSubs; s++ {
+		sender := common.BigToAddress(big.NewInt(int64(s) + 2))
+		reqs = append(reqs, pipeline.VRFRequest{
+			Sender:    sender,
+			SubID:     "sub",
+			RequestID: fmt.Sprintf("other-%d", s),
+		})
+	}
+
+	done := make(chan struct{})
+	go func() {
+		lsn.dispatchRequests(reqs)
+		close(done)
+	}()
+
+	// Every other subscription's request should start well before the
+	// skewed subscription's 20-request backlog (200ms+ at 10ms/request)
+	// finishes draining, since it must not be sharing a worker-pool slot
+	// with the skewed subscription's queue.
+	for i := 0; i < otherSubs; i++ {
+		select {
+		case <-otherSubsStarted:
+		case <-time.After(150 * time.Millisecond):
+			t.Fatalf("other subscription's request did not start promptly; it was starved behind the skewed subscription's backlog")
+		}
+	}
+
+	<-done
+	require.False(t, concurrentSubViolation, "requests for the same (sender, subID) ran concurrently")
+	assert.Greater(t, maxConcurrentSubs, 1, "expected requests for different subscriptions to overlap, got no concurrency")
+}
+
+// TestListenerV2_DispatchRequests_SlowSubDoesNotStarveOthers verifies that a
+// subscription whose pipeline run blocks for a long time (the same shape as
+// a request retrying through runPipelineWithBackoff's exponential backoff,
+// which can hold a subscription's queue for up to its configured
+// maxElapsed) only ties up the one worker-pool slot assigned to its own
+// queue, leaving the rest of VRFRequestConcurrency free for other
+// subscriptions to make progress.
+func TestListenerV2_DispatchRequests_SlowSubDoesNotStarveOthers(t *testing.T) {
+	const concurrency = 2
+	const otherSubs = 3
+
+	lsn := newTestListener(t, concurrency)
+
+	slowSubUnblock := make(chan struct{})
+	otherSubsDone := make(chan string, otherSubs)
+
+	lsn.pipelineOverride = func(req pipeline.VRFRequest) error {
+		if req.SubID == "slow" {
+			<-slowSubUnblock
+			return nil
+		}
+		otherSubsDone <- req.Sender.Hex() + ":" + req.SubID
+		return nil
+	}
+
+	var reqs []pipeline.VRFRequest
+	reqs = append(reqs, pipeline.VRFRequest{
+		Sender:    common.BigToAddress(big.NewInt(1)),
+		SubID:     "slow",
+		RequestID: "slow-0",
+	})
+	for s := 0; s < otherSubs; s++ {
+		reqs = append(reqs, pipeline.VRFRequest{
+			Sender:    common.BigToAddress(big.NewInt(int64(s) + 2)),
+			SubID:     "sub",
+			RequestID: fmt.Sprintf("other-%d", s),
+		})
+	}
+
+	done := make(chan struct{})
+	go func() {
+		lsn.dispatchRequests(reqs)
+		close(done)
+	}()
+
+	for i := 0; i < otherSubs; i++ {
+		select {
+		case <-otherSubsDone:
+		case <-time.After(time.Second):
+			t.Fatalf("other subscription did not complete while the slow subscription's request was still blocked")
+		}
+	}
+
+	close(slowSubUnblock)
+	<-done
+}
+
+// TestListenerV2_RespCount_AtomicUnderConcurrency flushes many overlapping
+// batches concurrently, the same way fulfillmentBatcher's flushLocked
+// dispatches each flush onto its own goroutine, and asserts respCount ends
+// up with exactly the expected totals. Run with `go test -race`, this would
+// flag a version of flushBatch that forgot to guard its respCount writes
+// with respCountMu.
+func TestListenerV2_RespCount_AtomicUnderConcurrency(t *testing.T) {
+	const numRequests = 50
+	const batchesPerRequest = 4
+
+	lsn := newTestListener(t, 8)
+
+	var wg sync.WaitGroup
+	for b := 0; b < batchesPerRequest; b++ {
+		var batch []batchedFulfillment
+		for i := 0; i < numRequests; i++ {
+			sender := common.BigToAddress(big.NewInt(int64(i) + 1))
+			batch = append(batch, batchedFulfillment{
+				req: pipeline.VRFRequest{
+					Sender:    sender,
+					SubID:     "sub",
+					RequestID: sender.Hex(),
+				},
+			})
+		}
+		wg.Add(1)
+		go func(batch []batchedFulfillment) {
+			defer wg.Done()
+			lsn.flushBatch(batch)
+		}(batch)
+	}
+	wg.Wait()
+
+	lsn.respCountMu.Lock()
+	defer lsn.respCountMu.Unlock()
+	assert.Len(t, lsn.respCount, numRequests)
+	for reqID, count := range lsn.respCount {
+		assert.Equal(t, uint64(batchesPerRequest), count, "requestID %s", reqID)
+	}
+}
@@ -0,0 +1,116 @@
+package vrf
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// inspectableListener is the subset of listenerV2's behavior the Registry
+// and its RPC surface need in order to support runtime introspection and
+// control, without depending on the concrete listener type. listenerV1
+// is expected to grow an equivalent implementation alongside its own
+// ServiceCtx migration.
+type inspectableListener interface {
+	// RespCountSnapshot returns a point-in-time copy of the listener's
+	// respCount map, keyed by request ID as a string.
+	RespCountSnapshot() map[string]uint64
+	// SetPaused stops (true) or resumes (false) request pickup without
+	// tearing down the underlying ServiceCtx.
+	SetPaused(paused bool)
+	// Replay re-delivers the log for requestID to the listener's mailbox,
+	// as if it had just been observed by the log broadcaster.
+	Replay(requestID string) error
+	// RebuildStartingCounts recomputes respCount from eth_txes, discarding
+	// whatever has accumulated in memory since the listener started.
+	RebuildStartingCounts() error
+}
+
+// Registry tracks the running VRF listener for every active job, keyed by
+// job ID, so that an operator-facing surface (RPC handler, CLI) can find
+// and act on a specific job's listener without reaching into Delegate's
+// internals.
+type Registry struct {
+	mu        sync.RWMutex
+	listeners map[int32]inspectableListener
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{listeners: make(map[int32]inspectableListener)}
+}
+
+// Register associates jobID with listener, replacing any previous entry.
+// Delegate.ServicesForSpec calls this for every listenerV2 it constructs;
+// listenerV1 instances are not registered.
+func (r *Registry) Register(jobID int32, listener inspectableListener) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners[jobID] = listener
+}
+
+// Unregister removes jobID's listener, e.g. once its ServiceCtx is closed.
+func (r *Registry) Unregister(jobID int32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.listeners, jobID)
+}
+
+// Jobs returns the IDs of every job with an active listener.
+func (r *Registry) Jobs() []int32 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := make([]int32, 0, len(r.listeners))
+	for id := range r.listeners {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (r *Registry) get(jobID int32) (inspectableListener, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	listener, ok := r.listeners[jobID]
+	if !ok {
+		return nil, errors.Errorf("no active VRF listener for job %d", jobID)
+	}
+	return listener, nil
+}
+
+// RespCounts returns the in-memory fulfillment counts for jobID's listener.
+func (r *Registry) RespCounts(jobID int32) (map[string]uint64, error) {
+	listener, err := r.get(jobID)
+	if err != nil {
+		return nil, err
+	}
+	return listener.RespCountSnapshot(), nil
+}
+
+// SetPaused pauses or resumes request pickup for jobID's listener.
+func (r *Registry) SetPaused(jobID int32, paused bool) error {
+	listener, err := r.get(jobID)
+	if err != nil {
+		return err
+	}
+	listener.SetPaused(paused)
+	return nil
+}
+
+// Replay re-delivers requestID to jobID's listener for re-processing.
+func (r *Registry) Replay(jobID int32, requestID string) error {
+	listener, err := r.get(jobID)
+	if err != nil {
+		return err
+	}
+	return listener.Replay(requestID)
+}
+
+// RebuildStartingCounts rebuilds jobID's listener's respCount from
+// eth_txes without restarting the node.
+func (r *Registry) RebuildStartingCounts(jobID int32) error {
+	listener, err := r.get(jobID)
+	if err != nil {
+		return err
+	}
+	return listener.RebuildStartingCounts()
+}
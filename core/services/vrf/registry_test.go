@@ -0,0 +1,141 @@
+package vrf
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeInspectableListener is a minimal inspectableListener recording the
+// calls made to it, so tests can assert Registry/AdminServer route
+// operator requests to the right listener.
+type fakeInspectableListener struct {
+	respCounts map[string]uint64
+	paused     bool
+	replayed   []string
+	rebuilt    bool
+	replayErr  error
+	rebuildErr error
+}
+
+func (f *fakeInspectableListener) RespCountSnapshot() map[string]uint64 { return f.respCounts }
+func (f *fakeInspectableListener) SetPaused(paused bool)                { f.paused = paused }
+func (f *fakeInspectableListener) Replay(requestID string) error {
+	f.replayed = append(f.replayed, requestID)
+	return f.replayErr
+}
+func (f *fakeInspectableListener) RebuildStartingCounts() error {
+	f.rebuilt = true
+	return f.rebuildErr
+}
+
+func TestRegistry_RegisterAndUnregister(t *testing.T) {
+	r := NewRegistry()
+	l := &fakeInspectableListener{}
+	r.Register(1, l)
+
+	assert.Equal(t, []int32{1}, r.Jobs())
+
+	r.Unregister(1)
+	assert.Empty(t, r.Jobs())
+
+	_, err := r.RespCounts(1)
+	require.Error(t, err, "operations against an unregistered job should fail, not reach a stale listener")
+}
+
+func TestRegistry_RespCounts(t *testing.T) {
+	r := NewRegistry()
+	l := &fakeInspectableListener{respCounts: map[string]uint64{"req1": 3}}
+	r.Register(1, l)
+
+	counts, err := r.RespCounts(1)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]uint64{"req1": 3}, counts)
+}
+
+func TestRegistry_SetPaused(t *testing.T) {
+	r := NewRegistry()
+	l := &fakeInspectableListener{}
+	r.Register(1, l)
+
+	require.NoError(t, r.SetPaused(1, true))
+	assert.True(t, l.paused)
+
+	require.NoError(t, r.SetPaused(1, false))
+	assert.False(t, l.paused)
+}
+
+func TestRegistry_Replay(t *testing.T) {
+	r := NewRegistry()
+	l := &fakeInspectableListener{}
+	r.Register(1, l)
+
+	require.NoError(t, r.Replay(1, "req1"))
+	assert.Equal(t, []string{"req1"}, l.replayed)
+}
+
+func TestRegistry_RebuildStartingCounts(t *testing.T) {
+	r := NewRegistry()
+	l := &fakeInspectableListener{}
+	r.Register(1, l)
+
+	require.NoError(t, r.RebuildStartingCounts(1))
+	assert.True(t, l.rebuilt)
+}
+
+func TestRegistry_UnknownJob_ReturnsError(t *testing.T) {
+	r := NewRegistry()
+	_, err := r.RespCounts(42)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "42")
+}
+
+func TestAdminServer_DelegatesToRegistry(t *testing.T) {
+	r := NewRegistry()
+	l := &fakeInspectableListener{respCounts: map[string]uint64{"req1": 1}}
+	r.Register(7, l)
+	s := NewAdminServer(r)
+	ctx := context.Background()
+
+	jobs, err := s.ListJobs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int32{7}, jobs)
+
+	counts, err := s.DumpRespCounts(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]uint64{"req1": 1}, counts)
+
+	require.NoError(t, s.Pause(ctx, 7))
+	assert.True(t, l.paused)
+
+	require.NoError(t, s.Resume(ctx, 7))
+	assert.False(t, l.paused)
+
+	require.NoError(t, s.Replay(ctx, 7, "req1"))
+	assert.Equal(t, []string{"req1"}, l.replayed)
+
+	require.NoError(t, s.RebuildStartingCounts(ctx, 7))
+	assert.True(t, l.rebuilt)
+}
+
+func TestAdminServer_Replay_RejectsEmptyRequestID(t *testing.T) {
+	r := NewRegistry()
+	l := &fakeInspectableListener{}
+	r.Register(7, l)
+	s := NewAdminServer(r)
+
+	err := s.Replay(context.Background(), 7, "")
+	require.Error(t, err)
+	assert.Empty(t, l.replayed, "an empty requestID must not reach the listener")
+}
+
+func TestAdminServer_UnknownJob_ReturnsError(t *testing.T) {
+	s := NewAdminServer(NewRegistry())
+	_, err := s.ListJobs(context.Background())
+	require.NoError(t, err, "ListJobs itself never fails, even with no active jobs")
+
+	_, err = s.DumpRespCounts(context.Background(), 99)
+	require.Error(t, err)
+}
@@ -0,0 +1,203 @@
+package vrf
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/smartcontractkit/sqlx"
+
+	httypes "github.com/smartcontractkit/chainlink/core/chains/evm/headtracker/types"
+	evmtypes "github.com/smartcontractkit/chainlink/core/chains/evm/types"
+	"github.com/smartcontractkit/chainlink/core/logger"
+	"github.com/smartcontractkit/chainlink/core/services/pg"
+)
+
+var (
+	promReorgsDetected = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "reorgs_detected_total",
+		Help: "The number of reorgs that unwound at least one VRF fulfillment",
+	})
+	promFulfillmentsReverted = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "vrf_fulfillments_reverted_total",
+		Help: "The number of VRF fulfillments reverted due to a reorg",
+	})
+)
+
+// reorgWindowDepth bounds how many blocks of fulfillment history the
+// tracker keeps around. It should be at least evmFinalityDepth, since a
+// canonical reorg can only unwind blocks that aren't yet finalized.
+const reorgWindowDepth = 256
+
+// blockFulfillments is the set of request IDs fulfilled in a single block,
+// keyed by that block so a later reorg can be matched back to exactly the
+// requests it invalidated.
+type blockFulfillments struct {
+	blockHash   common.Hash
+	blockNumber int64
+	requestIDs  []string
+}
+
+// reorgTracker watches the canonical chain via the HeadBroadcaster and
+// detects when a previously-seen block is no longer an ancestor of the
+// current head, i.e. a reorg unwound it. When that happens, the request IDs
+// recorded against the orphaned block are passed to onReverted and the
+// block is dropped from the window.
+//
+// The window is persisted via q so that a node restart doesn't forget about
+// fulfillments that are still within reorg range.
+type reorgTracker struct {
+	q               pg.Q
+	l               logger.Logger
+	chainID         uint64
+	headBroadcaster httypes.HeadBroadcaster
+
+	// onReverted is called with the request IDs that were fulfilled in an
+	// orphaned block, so the owning listener can decrement respCount.
+	onReverted func(requestIDs []string)
+
+	mu     sync.Mutex
+	window []blockFulfillments
+	unsub  func()
+}
+
+func newReorgTracker(
+	db *sqlx.DB,
+	pgCfg pg.LogConfig,
+	l logger.Logger,
+	chainID uint64,
+	headBroadcaster httypes.HeadBroadcaster,
+	onReverted func(requestIDs []string),
+) *reorgTracker {
+	return &reorgTracker{
+		q:               pg.NewQ(db, l, pgCfg),
+		l:               l.Named("VRFReorgTracker"),
+		chainID:         chainID,
+		headBroadcaster: headBroadcaster,
+		onReverted:      onReverted,
+	}
+}
+
+func (t *reorgTracker) Start() error {
+	if err := t.loadWindow(); err != nil {
+		t.l.Errorw("Unable to load persisted reorg window, starting empty", "err", err)
+	}
+	_, unsub := t.headBroadcaster.Subscribe(t)
+	t.unsub = unsub
+	return nil
+}
+
+func (t *reorgTracker) Close() error {
+	if t.unsub != nil {
+		t.unsub()
+	}
+	return nil
+}
+
+// RecordFulfillment adds requestIDs to the set of requests fulfilled in
+// (blockHash, blockNumber), persisting the merged set so it survives a
+// restart. More than one batch can land in the same block, so an existing
+// entry for blockHash is merged into rather than replaced.
+func (t *reorgTracker) RecordFulfillment(blockHash common.Hash, blockNumber int64, requestIDs []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	merged := false
+	for i := range t.window {
+		if t.window[i].blockHash == blockHash {
+			t.window[i].requestIDs = append(t.window[i].requestIDs, requestIDs...)
+			requestIDs = t.window[i].requestIDs
+			merged = true
+			break
+		}
+	}
+	if !merged {
+		t.window = append(t.window, blockFulfillments{
+			blockHash:   blockHash,
+			blockNumber: blockNumber,
+			requestIDs:  requestIDs,
+		})
+	}
+	t.pruneLocked(blockNumber)
+
+	if err := t.persistLocked(blockHash, blockNumber, requestIDs); err != nil {
+		t.l.Errorw("Unable to persist reorg window entry", "err", err, "blockNumber", blockNumber)
+	}
+}
+
+// OnNewLongestChain implements httypes.HeadTrackable. It checks whether any
+// block still held in the window has fallen off the canonical chain; if so,
+// the fulfillments recorded against it are reverted.
+func (t *reorgTracker) OnNewLongestChain(_ context.Context, head *evmtypes.Head) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	canonical := map[int64]common.Hash{}
+	for h := head; h != nil; h = h.Parent {
+		canonical[h.Number] = h.Hash
+	}
+
+	var kept []blockFulfillments
+	for _, bf := range t.window {
+		canonicalHash, ok := canonical[bf.blockNumber]
+		if ok && canonicalHash != bf.blockHash {
+			t.l.Warnw("Detected reorg affecting VRF fulfillments, reverting",
+				"blockNumber", bf.blockNumber, "orphanedHash", bf.blockHash, "canonicalHash", canonicalHash,
+				"requestIDs", bf.requestIDs)
+			promReorgsDetected.Inc()
+			promFulfillmentsReverted.Add(float64(len(bf.requestIDs)))
+			t.onReverted(bf.requestIDs)
+			continue
+		}
+		kept = append(kept, bf)
+	}
+	t.window = kept
+}
+
+// pruneLocked drops window entries older than reorgWindowDepth blocks
+// behind the given head block number. Must be called with t.mu held.
+func (t *reorgTracker) pruneLocked(headNumber int64) {
+	var kept []blockFulfillments
+	for _, bf := range t.window {
+		if headNumber-bf.blockNumber <= reorgWindowDepth {
+			kept = append(kept, bf)
+		}
+	}
+	t.window = kept
+}
+
+func (t *reorgTracker) persistLocked(blockHash common.Hash, blockNumber int64, requestIDs []string) error {
+	_, err := t.q.Exec(`
+INSERT INTO vrf_reorg_window (evm_chain_id, block_hash, block_number, request_ids)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (evm_chain_id, block_hash) DO UPDATE SET request_ids = EXCLUDED.request_ids, updated_at = now()
+	`, t.chainID, blockHash, blockNumber, pq.Array(requestIDs))
+	return err
+}
+
+func (t *reorgTracker) loadWindow() error {
+	var rows []struct {
+		BlockHash   common.Hash
+		BlockNumber int64
+		RequestIDs  pq.StringArray
+	}
+	err := t.q.Select(&rows, `
+SELECT block_hash, block_number, request_ids FROM vrf_reorg_window
+WHERE evm_chain_id = $1
+ORDER BY block_number ASC
+	`, t.chainID)
+	if err != nil {
+		return err
+	}
+	for _, r := range rows {
+		t.window = append(t.window, blockFulfillments{
+			blockHash:   r.BlockHash,
+			blockNumber: r.BlockNumber,
+			requestIDs:  []string(r.RequestIDs),
+		})
+	}
+	return nil
+}
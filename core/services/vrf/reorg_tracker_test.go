@@ -0,0 +1,132 @@
+package vrf
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	evmtypes "github.com/smartcontractkit/chainlink/core/chains/evm/types"
+	"github.com/smartcontractkit/chainlink/core/logger"
+)
+
+// newTestReorgTracker builds a reorgTracker directly from a struct literal,
+// bypassing newReorgTracker's pg.Q/sqlx.DB wiring, so pruneLocked and
+// OnNewLongestChain -- neither of which touch the DB -- can be unit tested
+// without a live Postgres instance. RecordFulfillment's persistence path is
+// intentionally not covered here; it needs a real DB and belongs in an
+// integration test instead.
+func newTestReorgTracker(t *testing.T, onReverted func([]string)) *reorgTracker {
+	return &reorgTracker{
+		l:          logger.TestLogger(t),
+		chainID:    1,
+		onReverted: onReverted,
+	}
+}
+
+func hashFor(b byte) common.Hash {
+	var h common.Hash
+	h[0] = b
+	return h
+}
+
+func TestReorgTracker_PruneLocked_DropsEntriesOlderThanWindowDepth(t *testing.T) {
+	tr := newTestReorgTracker(t, func([]string) {})
+	tr.window = []blockFulfillments{
+		{blockHash: hashFor(1), blockNumber: 100, requestIDs: []string{"r1"}},
+		{blockHash: hashFor(2), blockNumber: 100 + reorgWindowDepth, requestIDs: []string{"r2"}},
+	}
+
+	// headNumber far enough ahead that only the first entry falls outside
+	// reorgWindowDepth: it is reorgWindowDepth+1 blocks behind, while an
+	// entry exactly reorgWindowDepth blocks behind is still kept.
+	tr.pruneLocked(100 + reorgWindowDepth + 1)
+
+	assert.Len(t, tr.window, 1)
+	assert.Equal(t, hashFor(2), tr.window[0].blockHash)
+}
+
+func TestReorgTracker_OnNewLongestChain_RevertsOrphanedBlock(t *testing.T) {
+	var reverted []string
+	tr := newTestReorgTracker(t, func(reqIDs []string) {
+		reverted = append(reverted, reqIDs...)
+	})
+
+	orphanedHash := hashFor(0xAA)
+	tr.window = []blockFulfillments{
+		{blockHash: orphanedHash, blockNumber: 10, requestIDs: []string{"r1", "r2"}},
+	}
+
+	// The canonical chain at block 10 has a different hash than the one we
+	// recorded a fulfillment against, i.e. the original block was reorged
+	// out.
+	canonicalBlock10 := hashFor(0xBB)
+	head := &evmtypes.Head{
+		Number: 11,
+		Hash:   hashFor(0xCC),
+		Parent: &evmtypes.Head{
+			Number: 10,
+			Hash:   canonicalBlock10,
+		},
+	}
+
+	tr.OnNewLongestChain(context.Background(), head)
+
+	assert.ElementsMatch(t, []string{"r1", "r2"}, reverted)
+	assert.Empty(t, tr.window, "the orphaned entry should have been dropped from the window")
+}
+
+func TestReorgTracker_OnNewLongestChain_KeepsCanonicalBlock(t *testing.T) {
+	var reverted []string
+	tr := newTestReorgTracker(t, func(reqIDs []string) {
+		reverted = append(reverted, reqIDs...)
+	})
+
+	canonicalHash := hashFor(0xAA)
+	tr.window = []blockFulfillments{
+		{blockHash: canonicalHash, blockNumber: 10, requestIDs: []string{"r1"}},
+	}
+
+	head := &evmtypes.Head{
+		Number: 11,
+		Hash:   hashFor(0xCC),
+		Parent: &evmtypes.Head{
+			Number: 10,
+			Hash:   canonicalHash,
+		},
+	}
+
+	tr.OnNewLongestChain(context.Background(), head)
+
+	assert.Empty(t, reverted)
+	assert.Len(t, tr.window, 1, "a fulfillment recorded against a still-canonical block must not be reverted")
+}
+
+func TestReorgTracker_OnNewLongestChain_IgnoresBlocksNotYetObservedAsCanonical(t *testing.T) {
+	var reverted []string
+	tr := newTestReorgTracker(t, func(reqIDs []string) {
+		reverted = append(reverted, reqIDs...)
+	})
+
+	// blockNumber 10 is outside the range the chain walk below covers
+	// (only 11 and its single parent, 5, are walked), so the tracker has no
+	// canonical hash to compare against and must leave the entry alone.
+	tr.window = []blockFulfillments{
+		{blockHash: hashFor(0xAA), blockNumber: 10, requestIDs: []string{"r1"}},
+	}
+
+	head := &evmtypes.Head{
+		Number: 11,
+		Hash:   hashFor(0xCC),
+		Parent: &evmtypes.Head{
+			Number: 5,
+			Hash:   hashFor(0xDD),
+		},
+	}
+
+	tr.OnNewLongestChain(context.Background(), head)
+
+	assert.Empty(t, reverted)
+	assert.Len(t, tr.window, 1)
+}
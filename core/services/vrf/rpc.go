@@ -0,0 +1,164 @@
+package vrf
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// AdminServer exposes the Registry over HTTP/JSON for runtime introspection
+// and control of active VRF jobs, mirroring the admin/private API pattern
+// used by Ethereum clients (e.g. PrivateAdminAPI) applied to the VRF
+// subsystem. Call RegisterRoutes to mount it under the node's authenticated
+// API router alongside the other v2 routes.
+type AdminServer struct {
+	registry *Registry
+}
+
+// RegisterRoutes mounts AdminServer's endpoints under r. The caller is
+// expected to pass an already-authenticated router group, the same way
+// every other v2 admin route is registered.
+func (s *AdminServer) RegisterRoutes(r gin.IRouter) {
+	g := r.Group("/v2/vrf/jobs")
+	g.GET("", s.listJobs)
+	g.GET("/:jobID/resp_counts", s.dumpRespCounts)
+	g.POST("/:jobID/replay", s.replay)
+	g.POST("/:jobID/pause", s.pause)
+	g.POST("/:jobID/resume", s.resume)
+	g.POST("/:jobID/rebuild_starting_counts", s.rebuildStartingCounts)
+}
+
+func (s *AdminServer) listJobs(c *gin.Context) {
+	jobs, err := s.ListJobs(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs})
+}
+
+func (s *AdminServer) dumpRespCounts(c *gin.Context) {
+	jobID, err := jobIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	counts, err := s.DumpRespCounts(c.Request.Context(), jobID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"respCounts": counts})
+}
+
+func (s *AdminServer) replay(c *gin.Context) {
+	jobID, err := jobIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var body struct {
+		RequestID string `json:"requestID"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := s.Replay(c.Request.Context(), jobID, body.RequestID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (s *AdminServer) pause(c *gin.Context) {
+	jobID, err := jobIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := s.Pause(c.Request.Context(), jobID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (s *AdminServer) resume(c *gin.Context) {
+	jobID, err := jobIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := s.Resume(c.Request.Context(), jobID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (s *AdminServer) rebuildStartingCounts(c *gin.Context) {
+	jobID, err := jobIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := s.RebuildStartingCounts(c.Request.Context(), jobID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func jobIDFromParam(c *gin.Context) (int32, error) {
+	id, err := strconv.ParseInt(c.Param("jobID"), 10, 32)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid jobID")
+	}
+	return int32(id), nil
+}
+
+// NewAdminServer returns an AdminServer backed by registry.
+func NewAdminServer(registry *Registry) *AdminServer {
+	return &AdminServer{registry: registry}
+}
+
+// ListJobs returns the job IDs of every VRF job with an active listener.
+func (s *AdminServer) ListJobs(_ context.Context) ([]int32, error) {
+	return s.registry.Jobs(), nil
+}
+
+// DumpRespCounts returns a snapshot of the in-memory fulfillment counts
+// tracked for jobID.
+func (s *AdminServer) DumpRespCounts(_ context.Context, jobID int32) (map[string]uint64, error) {
+	return s.registry.RespCounts(jobID)
+}
+
+// Replay forces jobID's listener to re-process requestID, as if its log had
+// just arrived from the log broadcaster.
+func (s *AdminServer) Replay(_ context.Context, jobID int32, requestID string) error {
+	if requestID == "" {
+		return errors.New("requestID must not be empty")
+	}
+	return s.registry.Replay(jobID, requestID)
+}
+
+// Pause stops jobID's listener from picking up new requests, without
+// tearing down its ServiceCtx.
+func (s *AdminServer) Pause(_ context.Context, jobID int32) error {
+	return s.registry.SetPaused(jobID, true)
+}
+
+// Resume resumes request pickup for jobID's listener after a Pause.
+func (s *AdminServer) Resume(_ context.Context, jobID int32) error {
+	return s.registry.SetPaused(jobID, false)
+}
+
+// RebuildStartingCounts recomputes jobID's listener's respCount from
+// eth_txes without restarting the node.
+func (s *AdminServer) RebuildStartingCounts(_ context.Context, jobID int32) error {
+	return s.registry.RebuildStartingCounts(jobID)
+}
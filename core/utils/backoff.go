@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+// Clock abstracts time.Now so Backoff can be driven deterministically in
+// tests, per cenkalti/backoff's backoff.Clock interface.
+type Clock interface {
+	Now() time.Time
+}
+
+// Backoff wraps backoff.ExponentialBackOff with the handful of knobs
+// callers typically need (min/max interval, multiplier, max elapsed time),
+// plus jitter, which ExponentialBackOff already applies via
+// RandomizationFactor. It exists so services across the codebase retry
+// transient errors with the same tuning and testing story rather than each
+// rolling their own.
+type Backoff struct {
+	eb *backoff.ExponentialBackOff
+}
+
+// NewBackoff returns a Backoff that starts at minInterval and grows by
+// multiplier on each call to NextBackOff, capped at maxInterval, until
+// maxElapsed has passed since the Backoff was created (or last Reset), at
+// which point NextBackOff returns backoff.Stop.
+func NewBackoff(minInterval, maxInterval time.Duration, multiplier float64, maxElapsed time.Duration) *Backoff {
+	eb := backoff.NewExponentialBackOff()
+	eb.InitialInterval = minInterval
+	eb.MaxInterval = maxInterval
+	eb.Multiplier = multiplier
+	eb.MaxElapsedTime = maxElapsed
+	eb.Reset()
+	return &Backoff{eb: eb}
+}
+
+// WithClock overrides the clock Backoff uses to track elapsed time,
+// allowing deterministic tests. It re-Resets the Backoff so that
+// MaxElapsedTime is measured against the injected clock from this point
+// forward, rather than against the real-time start captured by NewBackoff.
+func (b *Backoff) WithClock(c Clock) *Backoff {
+	b.eb.Clock = c
+	b.eb.Reset()
+	return b
+}
+
+// NextBackOff returns how long to wait before the next retry, or
+// backoff.Stop if MaxElapsedTime has been exceeded.
+func (b *Backoff) NextBackOff() time.Duration {
+	return b.eb.NextBackOff()
+}
+
+// Reset clears accumulated elapsed time, as if the Backoff were newly
+// created. Call this after a successful attempt.
+func (b *Backoff) Reset() {
+	b.eb.Reset()
+}
+
+// Stop is returned by NextBackOff once MaxElapsedTime has elapsed.
+const Stop = backoff.Stop
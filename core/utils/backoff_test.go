@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a manually-advanced Clock, used to drive Backoff's
+// MaxElapsedTime deterministically instead of depending on real wall time.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestBackoff_WithClock_IsDeterministic(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := NewBackoff(10*time.Millisecond, 100*time.Millisecond, 2, 50*time.Millisecond).WithClock(clock)
+
+	wait := b.NextBackOff()
+	require.NotEqual(t, Stop, wait)
+
+	// Advance the fake clock past MaxElapsedTime. A real-time-backed
+	// Backoff would not observe this until 50ms of wall time actually
+	// passed; with the injected clock, the very next call sees it.
+	clock.Advance(100 * time.Millisecond)
+
+	assert.Equal(t, Stop, b.NextBackOff())
+}
+
+func TestBackoff_Reset_ClearsElapsedTime(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := NewBackoff(10*time.Millisecond, 100*time.Millisecond, 2, 50*time.Millisecond).WithClock(clock)
+
+	clock.Advance(100 * time.Millisecond)
+	require.Equal(t, Stop, b.NextBackOff())
+
+	b.Reset()
+	assert.NotEqual(t, Stop, b.NextBackOff())
+}